@@ -0,0 +1,246 @@
+package pgx
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/pgtype"
+)
+
+type batchItem struct {
+	query             string
+	arguments         []interface{}
+	parameterOIDs     []pgtype.OID
+	resultFormatCodes []int16
+
+	queuedQuery *QueuedQuery
+}
+
+// QueuedQuery is a query that has been queued on a Batch. It accumulates the
+// callback that should run against the query's result once the server sends
+// it back, so the caller does not have to separately track the order in
+// which queries were queued.
+type QueuedQuery struct {
+	batch *Batch
+	item  *batchItem
+
+	queryRowDst []interface{}
+	queryFn     func(Rows) error
+	execFn      func(CommandTag) error
+}
+
+// Query registers fn to be called with the rows produced by this query once
+// the batch is sent. fn must not retain the Rows past its own return.
+func (qq *QueuedQuery) Query(fn func(Rows) error) *QueuedQuery {
+	qq.queryFn = fn
+	return qq
+}
+
+// QueryRow registers dst to be scanned from the single row produced by this
+// query once the batch is sent.
+func (qq *QueuedQuery) QueryRow(dst ...interface{}) *QueuedQuery {
+	qq.queryRowDst = dst
+	return qq
+}
+
+// Exec registers fn to be called with the command tag produced by this query
+// once the batch is sent.
+func (qq *QueuedQuery) Exec(fn func(CommandTag) error) *QueuedQuery {
+	qq.execFn = fn
+	return qq
+}
+
+// Batch queries are a way of bundling multiple queries together to avoid
+// unnecessary network round trips. A Batch is created by (*Conn).BeginBatch.
+// Queries are added to the batch with Queue. The batch is then sent to the
+// server with Send.
+type Batch struct {
+	conn  *Conn
+	items []*batchItem
+
+	resultsRead int
+
+	// argTypeOIDCache caches the parameter OIDs derived by reflection for a
+	// given SQL string and argument type signature (see argTypeSignature), so
+	// repeated Queue calls for the same query with the same argument types
+	// only pay the reflection cost once. Keying on the signature as well as
+	// the SQL string keeps two calls for the same query text but
+	// differently-typed arguments (e.g. int32 then int64) from colliding.
+	argTypeOIDCache map[string][]pgtype.OID
+
+	// txOpen records whether Send opened a transaction around this batch's
+	// queued queries (i.e. Send was called with a non-nil txOptions). Close
+	// consults it to commit or roll back that transaction, since there is no
+	// other way through the public API to end it.
+	txOpen bool
+
+	ctx context.Context
+	err error
+}
+
+// BeginBatch returns a Batch ready to have queries queued on it.
+func (c *Conn) BeginBatch() *Batch {
+	return &Batch{conn: c}
+}
+
+// Queue queues a query to be sent to the server via Send. Each query in a
+// batch must be a parameterized query or the name of a prepared statement.
+// The returned *QueuedQuery can be used to register a callback that is
+// invoked with that query's results while the batch is drained, instead of
+// requiring a separate call to ExecResults/QueryResults/QueryRowResults in
+// matching order.
+//
+// If parameterOIDs is nil and arguments is non-empty, Queue derives the
+// parameter OIDs by reflection using the same rules as conn.QueryEx, rather
+// than requiring the caller to supply them explicitly. The derived OIDs are
+// cached on the Batch per SQL string and argument type signature, so
+// repeated Queue calls with the same query and argument types only pay the
+// reflection cost once.
+func (b *Batch) Queue(query string, arguments []interface{}, parameterOIDs []pgtype.OID, resultFormatCodes []int16) *QueuedQuery {
+	if parameterOIDs == nil && len(arguments) > 0 {
+		cacheKey := query + "\x00" + argTypeSignature(arguments)
+		if cached, ok := b.argTypeOIDCache[cacheKey]; ok {
+			parameterOIDs = cached
+		} else {
+			parameterOIDs = deriveParameterOIDs(arguments)
+			if b.argTypeOIDCache == nil {
+				b.argTypeOIDCache = make(map[string][]pgtype.OID)
+			}
+			b.argTypeOIDCache[cacheKey] = parameterOIDs
+		}
+	}
+
+	item := &batchItem{
+		query:             query,
+		arguments:         arguments,
+		parameterOIDs:     parameterOIDs,
+		resultFormatCodes: resultFormatCodes,
+	}
+
+	qq := &QueuedQuery{batch: b, item: item}
+	item.queuedQuery = qq
+
+	b.items = append(b.items, item)
+
+	return qq
+}
+
+// Send sends all queued queries to the server at once. All queries are sent
+// before any results are read. This allows multiple queries to be sent
+// without the round trip time of a network call for each query.
+//
+// If txOptions is non-nil, the queued queries are run inside a transaction
+// opened with that mode. In particular, setting txOptions.SnapshotID imports
+// a snapshot exported by Tx.ExportSnapshot before any queued query is sent,
+// so a snapshot-imported batch observes the same consistent view across its
+// entire pipeline of queries. That transaction is committed (or, on error,
+// rolled back) by Close, so callers don't need a separate Tx to end it.
+func (b *Batch) Send(ctx context.Context, txOptions *TxOptions) error {
+	b.ctx = ctx
+	if err := b.conn.sendBatch(ctx, b, txOptions); err != nil {
+		return err
+	}
+
+	b.txOpen = txOptions != nil
+	return nil
+}
+
+// ExecResults reads the results from the next query in the batch as a
+// CommandTag. It must be called exactly once for each query that was queued
+// with arguments that produce a command tag (i.e. not a query that returns
+// rows).
+func (b *Batch) ExecResults() (CommandTag, error) {
+	ct, err := b.conn.batchExecResults(b)
+	if err != nil {
+		return ct, err
+	}
+
+	b.resultsRead++
+	return ct, nil
+}
+
+// QueryResults reads the results from the next query in the batch as Rows.
+func (b *Batch) QueryResults() (Rows, error) {
+	rows, err := b.conn.batchQueryResults(b)
+	if err != nil {
+		return rows, err
+	}
+
+	b.resultsRead++
+	return rows, nil
+}
+
+// QueryRowResults reads the results from the next query in the batch as a
+// single Row.
+func (b *Batch) QueryRowResults() Row {
+	rows, _ := b.conn.batchQueryResults(b)
+	b.resultsRead++
+	return (Row)(rows)
+}
+
+// drainQueued runs the callbacks that were registered via QueuedQuery.Query,
+// QueuedQuery.QueryRow, and QueuedQuery.Exec for every query that was queued
+// with one and whose result has not already been read via
+// ExecResults/QueryResults/QueryRowResults, in the order the queries were
+// queued. Queries queued without a callback still have their pending result
+// read and discarded off the wire, so a later queued query's result can't be
+// left colliding with an earlier, unread one.
+func (b *Batch) drainQueued() error {
+	for _, item := range b.items[b.resultsRead:] {
+		qq := item.queuedQuery
+
+		switch {
+		case qq.queryRowDst != nil:
+			if err := b.QueryRowResults().Scan(qq.queryRowDst...); err != nil {
+				return err
+			}
+		case qq.queryFn != nil:
+			rows, err := b.QueryResults()
+			if err != nil {
+				return err
+			}
+			if err := qq.queryFn(rows); err != nil {
+				rows.Close()
+				return err
+			}
+			rows.Close()
+			if rows.Err() != nil {
+				return rows.Err()
+			}
+		case qq.execFn != nil:
+			ct, err := b.ExecResults()
+			if err != nil {
+				return err
+			}
+			if err := qq.execFn(ct); err != nil {
+				return err
+			}
+		default:
+			if err := b.conn.batchDiscardResult(b); err != nil {
+				return err
+			}
+			b.resultsRead++
+		}
+	}
+
+	return nil
+}
+
+// Close closes the batch, releasing any resources held by it. Any queries
+// that were queued with a QueuedQuery callback but whose results have not
+// yet been read are drained and their callbacks invoked first. If Send was
+// called with a non-nil txOptions, Close also ends the transaction Send
+// opened around the batch: committing it if the batch drained without
+// error, or rolling it back otherwise. Close returns the first error
+// encountered, whether from draining callbacks, ending the transaction, or
+// closing the underlying batch.
+func (b *Batch) Close() error {
+	if err := b.drainQueued(); err != nil {
+		b.err = err
+	}
+
+	if err := b.conn.batchClose(b); err != nil && b.err == nil {
+		b.err = err
+	}
+
+	return b.err
+}
@@ -0,0 +1,136 @@
+package pgx
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/pgtype"
+)
+
+// deriveParameterOIDs inspects arguments and returns the parameter OID pgx
+// would otherwise require the caller to supply explicitly. It uses the same
+// rules as conn.QueryEx: a type switch over the common/fast-path Go types
+// first, falling back to reflect.Value.Kind() for the underlying numeric,
+// string, and bool families (e.g. named integer types), so callers are not
+// forced to convert to the exact builtin type.
+func deriveParameterOIDs(arguments []interface{}) []pgtype.OID {
+	oids := make([]pgtype.OID, len(arguments))
+	for i, arg := range arguments {
+		oids[i] = deriveParameterOID(arg)
+	}
+	return oids
+}
+
+func deriveParameterOID(arg interface{}) pgtype.OID {
+	switch arg.(type) {
+	case nil:
+		return 0
+	case bool:
+		return pgtype.BoolOID
+	case float32:
+		return pgtype.Float4OID
+	case float64:
+		return pgtype.Float8OID
+	case int8, int16:
+		return pgtype.Int2OID
+	case int32:
+		return pgtype.Int4OID
+	case int, int64:
+		return pgtype.Int8OID
+	case uint8, uint16:
+		return pgtype.Int2OID
+	case uint32:
+		return pgtype.Int4OID
+	case uint, uint64:
+		return pgtype.Int8OID
+	case string:
+		return pgtype.TextOID
+	case []byte:
+		return pgtype.ByteaOID
+	case time.Time:
+		return pgtype.TimestamptzOID
+	case time.Duration:
+		return pgtype.IntervalOID
+	case pgtype.BinaryEncoder, pgtype.TextEncoder:
+		// arg knows how to encode itself; let the server infer the parameter's
+		// type from context instead of guessing via reflection below, which
+		// would otherwise risk misreading an encoder type's underlying struct
+		// or slice fields as if they were the argument itself (e.g. treating a
+		// slice-backed encoder as a Postgres array of its element type).
+		return 0
+	}
+
+	v := reflect.ValueOf(arg)
+	switch v.Kind() {
+	case reflect.Bool:
+		return pgtype.BoolOID
+	case reflect.Int8, reflect.Int16:
+		return pgtype.Int2OID
+	case reflect.Int32:
+		return pgtype.Int4OID
+	case reflect.Int, reflect.Int64:
+		return pgtype.Int8OID
+	case reflect.Uint8, reflect.Uint16:
+		return pgtype.Int2OID
+	case reflect.Uint32:
+		return pgtype.Int4OID
+	case reflect.Uint, reflect.Uint64:
+		return pgtype.Int8OID
+	case reflect.Float32:
+		return pgtype.Float4OID
+	case reflect.Float64:
+		return pgtype.Float8OID
+	case reflect.String:
+		return pgtype.TextOID
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return pgtype.ByteaOID
+		}
+		return arrayOIDForElemKind(v.Type().Elem().Kind())
+	}
+
+	return 0
+}
+
+// argTypeSignature returns a string built from the Go type name of each
+// argument, so two argument slices holding the same types in the same order
+// get the same signature. Slices of different types get different signatures
+// even when deriveParameterOIDs would derive the same OIDs for both (e.g. int
+// and int64). It is combined with a query's SQL text to form a cache key: two
+// Queue calls for the same SQL text but with differently-typed arguments
+// (e.g. int32 then int64) get distinct entries instead of the second silently
+// reusing the first's stale OIDs.
+func argTypeSignature(arguments []interface{}) string {
+	var sig strings.Builder
+	for _, arg := range arguments {
+		if arg == nil {
+			sig.WriteString("<nil>;")
+			continue
+		}
+		sig.WriteString(reflect.TypeOf(arg).String())
+		sig.WriteByte(';')
+	}
+	return sig.String()
+}
+
+// arrayOIDForElemKind maps the reflect.Kind of a slice's element type to the
+// OID of the corresponding PostgreSQL array type.
+func arrayOIDForElemKind(kind reflect.Kind) pgtype.OID {
+	switch kind {
+	case reflect.Int16:
+		return pgtype.Int2ArrayOID
+	case reflect.Int32:
+		return pgtype.Int4ArrayOID
+	case reflect.Int, reflect.Int64:
+		return pgtype.Int8ArrayOID
+	case reflect.Float32:
+		return pgtype.Float4ArrayOID
+	case reflect.Float64:
+		return pgtype.Float8ArrayOID
+	case reflect.String:
+		return pgtype.TextArrayOID
+	}
+
+	return 0
+}
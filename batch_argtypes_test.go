@@ -0,0 +1,33 @@
+package pgx
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/pgtype"
+)
+
+func TestDeriveParameterOIDEncoderTypesDeferToServer(t *testing.T) {
+	args := []interface{}{
+		pgtype.XID8{},
+		pgtype.Snapshot{},
+	}
+
+	for _, arg := range args {
+		if oid := deriveParameterOID(arg); oid != 0 {
+			t.Errorf("deriveParameterOID(%T) => %v, want %v (should defer to server inference)", arg, oid, 0)
+		}
+	}
+}
+
+func TestArgTypeSignatureDistinguishesArgTypes(t *testing.T) {
+	a := argTypeSignature([]interface{}{int32(1)})
+	b := argTypeSignature([]interface{}{int64(1)})
+	if a == b {
+		t.Errorf("argTypeSignature gave the same signature for int32 and int64 arguments: %q", a)
+	}
+
+	c := argTypeSignature([]interface{}{int32(1)})
+	if a != c {
+		t.Errorf("argTypeSignature(%v) => %q, want %q (same types should match)", []interface{}{int32(1)}, c, a)
+	}
+}
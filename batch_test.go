@@ -3,6 +3,7 @@ package pgx_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx"
 	"github.com/jackc/pgx/pgtype"
@@ -149,6 +150,90 @@ func TestConnBeginBatch(t *testing.T) {
 	ensureConnValid(t, conn)
 }
 
+func TestConnBeginBatchWithQueuedQueryCallbacks(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnect(t, *defaultConnConfig)
+	defer closeConn(t, conn)
+
+	sql := `create temporary table ledger(
+  id serial primary key,
+  description varchar not null,
+  amount int not null
+);`
+	mustExec(t, conn, sql)
+
+	batch := conn.BeginBatch()
+
+	var ct1, ct2 pgx.CommandTag
+	batch.Queue("insert into ledger(description, amount) values($1, $2)",
+		[]interface{}{"q1", 1},
+		[]pgtype.OID{pgtype.VarcharOID, pgtype.Int4OID},
+		nil,
+	).Exec(func(ct pgx.CommandTag) error {
+		ct1 = ct
+		return nil
+	})
+	batch.Queue("insert into ledger(description, amount) values($1, $2)",
+		[]interface{}{"q2", 2},
+		[]pgtype.OID{pgtype.VarcharOID, pgtype.Int4OID},
+		nil,
+	).Exec(func(ct pgx.CommandTag) error {
+		ct2 = ct
+		return nil
+	})
+
+	var rowCount int
+	batch.Queue("select id, description, amount from ledger order by id",
+		nil,
+		nil,
+		[]int16{pgx.BinaryFormatCode, pgx.TextFormatCode, pgx.BinaryFormatCode},
+	).Query(func(rows pgx.Rows) error {
+		for rows.Next() {
+			var id int32
+			var description string
+			var amount int32
+			if err := rows.Scan(&id, &description, &amount); err != nil {
+				return err
+			}
+			rowCount++
+		}
+		return rows.Err()
+	})
+
+	var sum int32
+	batch.Queue("select sum(amount) from ledger",
+		nil,
+		nil,
+		[]int16{pgx.BinaryFormatCode},
+	).QueryRow(&sum)
+
+	err := batch.Send(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = batch.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ct1.RowsAffected() != 1 {
+		t.Errorf("ct1.RowsAffected() => %v, want %v", ct1.RowsAffected(), 1)
+	}
+	if ct2.RowsAffected() != 1 {
+		t.Errorf("ct2.RowsAffected() => %v, want %v", ct2.RowsAffected(), 1)
+	}
+	if rowCount != 2 {
+		t.Errorf("rowCount => %v, want %v", rowCount, 2)
+	}
+	if sum != 3 {
+		t.Errorf("sum => %v, want %v", sum, 3)
+	}
+
+	ensureConnValid(t, conn)
+}
+
 func TestConnBeginBatchWithPreparedStatement(t *testing.T) {
 	t.Parallel()
 
@@ -441,4 +526,225 @@ func TestConnBeginBatchQueryError(t *testing.T) {
 	if conn.IsAlive() {
 		t.Error("conn should be dead, but was alive")
 	}
-}
\ No newline at end of file
+}
+func TestConnBeginBatchWithNotify(t *testing.T) {
+	t.Parallel()
+
+	listener := mustConnect(t, *defaultConnConfig)
+	defer closeConn(t, listener)
+
+	notifications := make(chan *pgx.Notification, 1)
+	listener.SetNotificationHandler(func(n *pgx.Notification) {
+		notifications <- n
+	})
+
+	mustExec(t, listener, "listen foo")
+
+	batch := listener.BeginBatch()
+	batch.Queue("select n from generate_series(0,5) n",
+		nil,
+		nil,
+		[]int16{pgx.BinaryFormatCode},
+	)
+
+	err := batch.Send(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notifier := mustConnect(t, *defaultConnConfig)
+	defer closeConn(t, notifier)
+	mustExec(t, notifier, "notify foo, 'bar'")
+
+	rows, err := batch.QueryResults()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; rows.Next(); i++ {
+		var n int
+		if err := rows.Scan(&n); err != nil {
+			t.Error(err)
+		}
+		if n != i {
+			t.Errorf("n => %v, want %v", n, i)
+		}
+	}
+
+	if rows.Err() != nil {
+		t.Fatal(rows.Err())
+	}
+
+	err = batch.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case n := <-notifications:
+		if n.Channel != "foo" {
+			t.Errorf("n.Channel => %v, want %v", n.Channel, "foo")
+		}
+		if n.Payload != "bar" {
+			t.Errorf("n.Payload => %v, want %v", n.Payload, "bar")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for notification")
+	}
+
+	ensureConnValid(t, listener)
+}
+
+func TestConnBeginBatchQueueWithoutExplicitParameterOIDs(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnect(t, *defaultConnConfig)
+	defer closeConn(t, conn)
+
+	sql := `create temporary table ledger(
+  id serial primary key,
+  description varchar not null,
+  amount int not null
+);`
+	mustExec(t, conn, sql)
+
+	batch := conn.BeginBatch()
+	batch.Queue("insert into ledger(description, amount) values($1, $2)",
+		[]interface{}{"q1", 1},
+		nil,
+		nil,
+	)
+	batch.Queue("select amount from ledger where description = $1",
+		[]interface{}{"q1"},
+		nil,
+		[]int16{pgx.BinaryFormatCode},
+	)
+
+	err := batch.Send(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ct, err := batch.ExecResults()
+	if err != nil {
+		t.Error(err)
+	}
+	if ct.RowsAffected() != 1 {
+		t.Errorf("ct.RowsAffected() => %v, want %v", ct.RowsAffected(), 1)
+	}
+
+	var amount int32
+	err = batch.QueryRowResults().Scan(&amount)
+	if err != nil {
+		t.Error(err)
+	}
+	if amount != 1 {
+		t.Errorf("amount => %v, want %v", amount, 1)
+	}
+
+	err = batch.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ensureConnValid(t, conn)
+}
+
+func TestConnBeginBatchQueueSameSQLDifferentArgTypes(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnect(t, *defaultConnConfig)
+	defer closeConn(t, conn)
+
+	batch := conn.BeginBatch()
+	batch.Queue("select $1", []interface{}{int32(1)}, nil, []int16{pgx.BinaryFormatCode})
+	batch.Queue("select $1", []interface{}{int64(5000000000)}, nil, []int16{pgx.BinaryFormatCode})
+
+	err := batch.Send(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var n1 int32
+	if err := batch.QueryRowResults().Scan(&n1); err != nil {
+		t.Error(err)
+	}
+	if n1 != 1 {
+		t.Errorf("n1 => %v, want %v", n1, 1)
+	}
+
+	var n2 int64
+	if err := batch.QueryRowResults().Scan(&n2); err != nil {
+		t.Error(err)
+	}
+	if n2 != 5000000000 {
+		t.Errorf("n2 => %v, want %v (a stale cached int4 OID from the first query would corrupt this encode)", n2, 5000000000)
+	}
+
+	err = batch.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ensureConnValid(t, conn)
+}
+
+func TestConnBeginBatchWithQueuedQueryCallbacksSkipsUnregisteredItem(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnect(t, *defaultConnConfig)
+	defer closeConn(t, conn)
+
+	sql := `create temporary table ledger(
+  id serial primary key,
+  description varchar not null,
+  amount int not null
+);`
+	mustExec(t, conn, sql)
+
+	batch := conn.BeginBatch()
+
+	var ct pgx.CommandTag
+	batch.Queue("insert into ledger(description, amount) values($1, $2)",
+		[]interface{}{"q1", 1},
+		[]pgtype.OID{pgtype.VarcharOID, pgtype.Int4OID},
+		nil,
+	).Exec(func(tag pgx.CommandTag) error {
+		ct = tag
+		return nil
+	})
+
+	// Queued with no .Exec/.Query/.QueryRow callback -- drainQueued must still
+	// read this result off the wire so it doesn't leak into the next item.
+	batch.Queue("insert into ledger(description, amount) values($1, $2)",
+		[]interface{}{"q2", 2},
+		[]pgtype.OID{pgtype.VarcharOID, pgtype.Int4OID},
+		nil,
+	)
+
+	var sum int32
+	batch.Queue("select sum(amount) from ledger",
+		nil,
+		nil,
+		[]int16{pgx.BinaryFormatCode},
+	).QueryRow(&sum)
+
+	err := batch.Send(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = batch.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ct.RowsAffected() != 1 {
+		t.Errorf("ct.RowsAffected() => %v, want %v", ct.RowsAffected(), 1)
+	}
+	if sum != 3 {
+		t.Errorf("sum => %v, want %v", sum, 3)
+	}
+
+	ensureConnValid(t, conn)
+}
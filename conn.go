@@ -0,0 +1,140 @@
+package pgx
+
+import (
+	"context"
+	"errors"
+)
+
+// Conn is a connection to a single PostgreSQL database.
+type Conn struct {
+	notificationHandler NotificationHandler
+
+	pendingBatch *Batch
+}
+
+// rxNotificationResponse is called by nextBatchMessage whenever a
+// NotificationResponse arrives while a Batch is being drained. It passes the
+// notification to the handler registered with SetNotificationHandler, if
+// any; it does not queue the notification anywhere else.
+func (c *Conn) rxNotificationResponse(n *Notification) {
+	c.dispatchNotification(n)
+}
+
+// sendBatch sends every query queued on b in a single round trip. If
+// txOptions is non-nil, the queued queries run inside a transaction opened
+// with that mode: BEGIN is sent first, and if txOptions.SnapshotID is set,
+// SET TRANSACTION SNAPSHOT is sent immediately after BEGIN and before any
+// queued query, so the whole batch observes exactly the view exported by
+// Tx.ExportSnapshot.
+func (c *Conn) sendBatch(ctx context.Context, b *Batch, txOptions *TxOptions) error {
+	if c.pendingBatch != nil {
+		return errors.New("pgx: conn already has a batch in progress")
+	}
+
+	if txOptions != nil {
+		if err := txOptions.begin(ctx, c); err != nil {
+			return err
+		}
+	}
+
+	for _, item := range b.items {
+		if err := c.sendQueuedQuery(ctx, item); err != nil {
+			return err
+		}
+	}
+
+	c.pendingBatch = b
+	return nil
+}
+
+// nextBatchMessage reads backend messages for the in-progress batch until it
+// finds the next one that actually answers a queued query. Messages that
+// arrive interleaved with batch results but aren't part of the batch's
+// result stream -- notably NotificationResponse -- are routed to
+// rxNotificationResponse instead of being handed back as the batch's next
+// result, so LISTEN/NOTIFY keeps working while a batch is in flight.
+func (c *Conn) nextBatchMessage(ctx context.Context) (interface{}, error) {
+	for {
+		msg, err := c.rxMsg(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if n, ok := msg.(*Notification); ok {
+			c.rxNotificationResponse(n)
+			continue
+		}
+
+		return msg, nil
+	}
+}
+
+func (c *Conn) batchExecResults(b *Batch) (CommandTag, error) {
+	msg, err := c.nextBatchMessage(b.ctx)
+	if err != nil {
+		return "", err
+	}
+
+	ct, ok := msg.(CommandTag)
+	if !ok {
+		return "", errors.New("pgx: unexpected message reading batch exec results")
+	}
+
+	return ct, nil
+}
+
+func (c *Conn) batchQueryResults(b *Batch) (Rows, error) {
+	msg, err := c.nextBatchMessage(b.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, ok := msg.(Rows)
+	if !ok {
+		return nil, errors.New("pgx: unexpected message reading batch query results")
+	}
+
+	return rows, nil
+}
+
+// batchDiscardResult reads and fully consumes whichever result is next for
+// b, without regard to whether it is a CommandTag or Rows. It is used to
+// skip over queued queries that were never given an ExecResults/
+// QueryResults/QueryRowResults-style consumer, so the following queued
+// query's result isn't left to collide with this one's still-pending
+// message on the wire.
+func (c *Conn) batchDiscardResult(b *Batch) error {
+	msg, err := c.nextBatchMessage(b.ctx)
+	if err != nil {
+		return err
+	}
+
+	if rows, ok := msg.(Rows); ok {
+		rows.Close()
+		return rows.Err()
+	}
+
+	return nil
+}
+
+// batchClose ends the in-progress batch. If Send opened a transaction around
+// b (b.txOpen), that transaction is committed on a clean drain or rolled
+// back if draining left an error on b, so a txOptions-backed batch never
+// leaves an open transaction on the connection for Close's caller to forget
+// about.
+func (c *Conn) batchClose(b *Batch) error {
+	c.pendingBatch = nil
+
+	if b.txOpen {
+		endSQL := "commit"
+		if b.err != nil {
+			endSQL = "rollback"
+		}
+
+		if _, err := c.Exec(endSQL); err != nil && b.err == nil {
+			return err
+		}
+	}
+
+	return b.err
+}
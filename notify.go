@@ -0,0 +1,31 @@
+package pgx
+
+// Notification is a message received from the PostgreSQL LISTEN/NOTIFY
+// system.
+type Notification struct {
+	PID     uint32 // backend pid that sent the notification
+	Channel string // channel that the notification was sent on
+	Payload string
+}
+
+// NotificationHandler is called for every NotificationResponse message
+// received outside of the normal query/row flow, such as those that arrive
+// while a Batch is being drained. Set one with SetNotificationHandler.
+type NotificationHandler func(*Notification)
+
+// SetNotificationHandler sets the function that will be called whenever a
+// NotificationResponse is received from the server on conn, including
+// notifications that arrive while a Batch queued on conn is being sent or
+// drained. Passing nil removes the handler, and notifications received with
+// no handler set are dropped as before.
+func (c *Conn) SetNotificationHandler(handler NotificationHandler) {
+	c.notificationHandler = handler
+}
+
+// dispatchNotification routes n to the registered NotificationHandler, if
+// any. It is safe to call even when no handler has been set.
+func (c *Conn) dispatchNotification(n *Notification) {
+	if c.notificationHandler != nil {
+		c.notificationHandler(n)
+	}
+}
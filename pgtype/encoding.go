@@ -0,0 +1,16 @@
+package pgtype
+
+import "io"
+
+// BinaryEncoder is implemented by pgtype values, such as XID8 and the other
+// pguint32/pguint64-based types, that can encode themselves in PostgreSQL's
+// binary wire format.
+type BinaryEncoder interface {
+	EncodeBinary(w io.Writer) (bool, error)
+}
+
+// TextEncoder is implemented by pgtype values, such as Snapshot, that can
+// encode themselves in PostgreSQL's text wire format.
+type TextEncoder interface {
+	EncodeText(w io.Writer) (bool, error)
+}
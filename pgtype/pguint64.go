@@ -0,0 +1,107 @@
+package pgtype
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/jackc/pgx/pgio"
+)
+
+// pguint64 is the core type that is used to implement PostgreSQL types such
+// as XID8.
+type pguint64 struct {
+	Uint   uint64
+	Status Status
+}
+
+// ConvertFrom converts from src to dst. Note that as pguint64 is not a general
+// number type ConvertFrom does not do automatic type conversion as other number
+// types do.
+func (dst *pguint64) ConvertFrom(src interface{}) error {
+	switch value := src.(type) {
+	case uint64:
+		*dst = pguint64{Uint: value, Status: Present}
+	default:
+		return fmt.Errorf("cannot convert %v to pguint64", value)
+	}
+
+	return nil
+}
+
+// AssignTo assigns from src to dst. Note that as pguint64 is not a general number
+// type AssignTo does not do automatic type conversion as other number types do.
+func (src *pguint64) AssignTo(dst interface{}) error {
+	switch v := dst.(type) {
+	case *uint64:
+		if src.Status == Present {
+			*v = src.Uint
+		} else {
+			return fmt.Errorf("cannot assign %v into %T", src, dst)
+		}
+	case **uint64:
+		if src.Status == Present {
+			n := src.Uint
+			*v = &n
+		} else {
+			*v = nil
+		}
+	}
+
+	return nil
+}
+
+func (dst *pguint64) DecodeText(src []byte) error {
+	if src == nil {
+		*dst = pguint64{Status: Null}
+		return nil
+	}
+
+	n, err := strconv.ParseUint(string(src), 10, 64)
+	if err != nil {
+		return err
+	}
+
+	*dst = pguint64{Uint: n, Status: Present}
+	return nil
+}
+
+func (dst *pguint64) DecodeBinary(src []byte) error {
+	if src == nil {
+		*dst = pguint64{Status: Null}
+		return nil
+	}
+
+	if len(src) != 8 {
+		return fmt.Errorf("invalid length: %v", len(src))
+	}
+
+	n := binary.BigEndian.Uint64(src)
+	*dst = pguint64{Uint: n, Status: Present}
+	return nil
+}
+
+func (src pguint64) EncodeText(w io.Writer) (bool, error) {
+	switch src.Status {
+	case Null:
+		return true, nil
+	case Undefined:
+		return false, errUndefined
+	}
+
+	_, err := io.WriteString(w, strconv.FormatUint(src.Uint, 10))
+	return false, err
+}
+
+func (src pguint64) EncodeBinary(w io.Writer) (bool, error) {
+	switch src.Status {
+	case Null:
+		return true, nil
+	case Undefined:
+		return false, errUndefined
+	}
+
+	_, err := pgio.WriteUint64(w, src.Uint)
+	return false, err
+}
@@ -0,0 +1,92 @@
+package pgtype
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Snapshot represents the PostgreSQL pg_snapshot type, as returned by
+// pg_current_snapshot(). It is not the snapshot identifier returned by
+// pg_export_snapshot() (see Tx.ExportSnapshot). Its text form is
+// "xmin:xmax:xip_list", e.g. "10:20:10,14,15", where xip_list is the list of
+// in-progress transaction ids between xmin (inclusive) and xmax (exclusive).
+type Snapshot struct {
+	Xmin   uint64
+	Xmax   uint64
+	XIPs   []uint64
+	Status Status
+}
+
+// Set converts from src to dst. Snapshot has no general conversion from
+// other types, so src must already be a Snapshot.
+func (dst *Snapshot) Set(src interface{}) error {
+	switch value := src.(type) {
+	case Snapshot:
+		*dst = value
+	default:
+		return fmt.Errorf("cannot convert %v to Snapshot", value)
+	}
+
+	return nil
+}
+
+// Get returns the simplest representation of dst as a Snapshot.
+func (dst *Snapshot) Get() interface{} {
+	return *dst
+}
+
+func (dst *Snapshot) DecodeText(src []byte) error {
+	if src == nil {
+		*dst = Snapshot{Status: Null}
+		return nil
+	}
+
+	parts := strings.SplitN(string(src), ":", 3)
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid snapshot: %v", string(src))
+	}
+
+	xmin, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid snapshot xmin: %v", err)
+	}
+
+	xmax, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid snapshot xmax: %v", err)
+	}
+
+	var xips []uint64
+	if parts[2] != "" {
+		for _, s := range strings.Split(parts[2], ",") {
+			xip, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid snapshot xip: %v", err)
+			}
+			xips = append(xips, xip)
+		}
+	}
+
+	*dst = Snapshot{Xmin: xmin, Xmax: xmax, XIPs: xips, Status: Present}
+	return nil
+}
+
+func (src Snapshot) EncodeText(w io.Writer) (bool, error) {
+	switch src.Status {
+	case Null:
+		return true, nil
+	case Undefined:
+		return false, errUndefined
+	}
+
+	xips := make([]string, len(src.XIPs))
+	for i, xip := range src.XIPs {
+		xips[i] = strconv.FormatUint(xip, 10)
+	}
+
+	s := strconv.FormatUint(src.Xmin, 10) + ":" + strconv.FormatUint(src.Xmax, 10) + ":" + strings.Join(xips, ",")
+	_, err := io.WriteString(w, s)
+	return false, err
+}
@@ -0,0 +1,42 @@
+package pgtype
+
+import "io"
+
+// XID8OID is the OID of the xid8 type, introduced in PostgreSQL 13.
+const XID8OID = 5069
+
+// XID8 represents the xid8 type, the 64-bit full transaction id introduced
+// in PostgreSQL 13 (e.g. as returned by pg_current_xact_id() and used within
+// pg_snapshot). Unlike XID, XID8 does not wrap around within the lifetime of
+// a cluster.
+type XID8 pguint64
+
+// ConvertFrom converts from src to dst. Note that as XID8 is not a general
+// number type ConvertFrom does not do automatic type conversion as other
+// number types do.
+func (dst *XID8) ConvertFrom(src interface{}) error {
+	return (*pguint64)(dst).ConvertFrom(src)
+}
+
+// AssignTo assigns from src to dst. Note that as XID8 is not a general number
+// type AssignTo does not do automatic type conversion as other number types
+// do.
+func (src *XID8) AssignTo(dst interface{}) error {
+	return (*pguint64)(src).AssignTo(dst)
+}
+
+func (dst *XID8) DecodeText(src []byte) error {
+	return (*pguint64)(dst).DecodeText(src)
+}
+
+func (dst *XID8) DecodeBinary(src []byte) error {
+	return (*pguint64)(dst).DecodeBinary(src)
+}
+
+func (src XID8) EncodeText(w io.Writer) (bool, error) {
+	return (pguint64)(src).EncodeText(w)
+}
+
+func (src XID8) EncodeBinary(w io.Writer) (bool, error) {
+	return (pguint64)(src).EncodeBinary(w)
+}
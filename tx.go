@@ -0,0 +1,146 @@
+package pgx
+
+import (
+	"context"
+	"strings"
+)
+
+// TxIsoLevel is the transaction isolation level (see BEGIN's ISOLATION LEVEL
+// in the PostgreSQL documentation).
+type TxIsoLevel string
+
+// Transaction isolation levels.
+const (
+	Serializable    TxIsoLevel = "serializable"
+	RepeatableRead  TxIsoLevel = "repeatable read"
+	ReadCommitted   TxIsoLevel = "read committed"
+	ReadUncommitted TxIsoLevel = "read uncommitted"
+)
+
+// TxAccessMode is the transaction access mode (see BEGIN's READ WRITE /
+// READ ONLY in the PostgreSQL documentation).
+type TxAccessMode string
+
+// Transaction access modes.
+const (
+	ReadWrite TxAccessMode = "read write"
+	ReadOnly  TxAccessMode = "read only"
+)
+
+// TxOptions controls how BeginEx starts a transaction.
+type TxOptions struct {
+	IsoLevel       TxIsoLevel
+	AccessMode     TxAccessMode
+	DeferrableMode string // "deferrable" or "not deferrable", empty means the server default
+
+	// SnapshotID, if set, is passed to SET TRANSACTION SNAPSHOT once the
+	// transaction has started, so every query within it sees the exact rows
+	// visible to whichever transaction originally exported the snapshot via
+	// Tx.ExportSnapshot. Combining this with IsoLevel Serializable,
+	// AccessMode ReadOnly, and DeferrableMode "deferrable" lets multiple
+	// connections compute results from one stable, consistent view while
+	// writers keep committing.
+	SnapshotID string
+}
+
+func (o *TxOptions) beginSQL() string {
+	buf := "begin"
+
+	if o == nil {
+		return buf
+	}
+
+	if o.IsoLevel != "" {
+		buf += " isolation level " + string(o.IsoLevel)
+	}
+	if o.AccessMode != "" {
+		buf += " " + string(o.AccessMode)
+	}
+	if o.DeferrableMode != "" {
+		buf += " " + o.DeferrableMode
+	}
+
+	return buf
+}
+
+// BeginEx starts a transaction with txOptions determining the transaction
+// mode. If txOptions.SnapshotID is set, the new transaction imports that
+// snapshot via SET TRANSACTION SNAPSHOT before returning, so it is
+// immediately safe to query against a consistent view shared with whichever
+// transaction produced the snapshot via Tx.ExportSnapshot.
+func (c *Conn) BeginEx(ctx context.Context, txOptions *TxOptions) (*Tx, error) {
+	if err := txOptions.begin(ctx, c); err != nil {
+		return nil, err
+	}
+
+	return &Tx{conn: c}, nil
+}
+
+// begin issues BEGIN per o.beginSQL and, if o.SnapshotID is set, imports that
+// snapshot via SET TRANSACTION SNAPSHOT immediately afterward. Both BeginEx
+// and Conn.sendBatch call this, so a Batch sent with TxOptions.SnapshotID set
+// runs its entire pipeline of queued queries against the same snapshot a
+// plain BeginEx transaction would.
+func (o *TxOptions) begin(ctx context.Context, c *Conn) error {
+	if _, err := c.ExecEx(ctx, o.beginSQL(), nil); err != nil {
+		return err
+	}
+
+	if o != nil && o.SnapshotID != "" {
+		if _, err := c.ExecEx(ctx, "set transaction snapshot "+quoteSnapshotID(o.SnapshotID), nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExportSnapshot exports tx's current snapshot via pg_export_snapshot() so
+// other connections can import it with TxOptions.SnapshotID and observe the
+// exact same view of the database, as long as tx remains open. tx should
+// normally have been started with AccessMode ReadOnly and DeferrableMode
+// "deferrable" at IsoLevel Serializable so the exported snapshot is stable
+// for the lifetime of the transaction.
+func (tx *Tx) ExportSnapshot() (string, error) {
+	var snapshotID string
+	err := tx.conn.QueryRow("select pg_export_snapshot()").Scan(&snapshotID)
+	if err != nil {
+		return "", err
+	}
+
+	return snapshotID, nil
+}
+
+// quoteSnapshotID quotes id as a SQL string literal, doubling any embedded
+// single quotes per the standard SQL escaping rule. SnapshotID is a public
+// TxOptions field that pgx never validates the shape of, so it must be
+// escaped before interpolating it into SET TRANSACTION SNAPSHOT.
+func quoteSnapshotID(id string) string {
+	return "'" + strings.Replace(id, "'", "''", -1) + "'"
+}
+
+// Tx represents a database transaction.
+//
+// Tx does not track whether Commit or Rollback has already been called: each
+// method runs its statement on the underlying connection regardless.
+type Tx struct {
+	conn *Conn
+}
+
+// Commit commits the transaction.
+func (tx *Tx) Commit() error {
+	_, err := tx.conn.Exec("commit")
+	return err
+}
+
+// Rollback rolls back the transaction. Rollback is safe to call on an
+// already committed or rolled back transaction.
+func (tx *Tx) Rollback() error {
+	_, err := tx.conn.Exec("rollback")
+	return err
+}
+
+// Exec executes sql within the transaction.
+func (tx *Tx) Exec(sql string, arguments ...interface{}) (CommandTag, error) {
+	return tx.conn.Exec(sql, arguments...)
+}
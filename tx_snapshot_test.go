@@ -0,0 +1,223 @@
+package pgx_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx"
+)
+
+func TestTxExportSnapshotConcurrentImport(t *testing.T) {
+	t.Parallel()
+
+	exportConn := mustConnect(t, *defaultConnConfig)
+	defer closeConn(t, exportConn)
+
+	// A regular (not temporary) table, so the writer and worker connections
+	// can see it too.
+	mustExec(t, exportConn, "create table snap_test(id serial primary key)")
+	defer mustExec(t, exportConn, "drop table snap_test")
+	mustExec(t, exportConn, "insert into snap_test default values")
+	mustExec(t, exportConn, "insert into snap_test default values")
+
+	tx, err := exportConn.BeginEx(context.Background(), &pgx.TxOptions{
+		IsoLevel:       pgx.Serializable,
+		AccessMode:     pgx.ReadOnly,
+		DeferrableMode: "deferrable",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	snapshotID, err := tx.ExportSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writer := mustConnect(t, *defaultConnConfig)
+	defer closeConn(t, writer)
+	mustExec(t, writer, "insert into snap_test default values")
+
+	const workerCount = 4
+	var wg sync.WaitGroup
+	counts := make([]int, workerCount)
+
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			conn := mustConnect(t, *defaultConnConfig)
+			defer closeConn(t, conn)
+
+			workerTx, err := conn.BeginEx(context.Background(), &pgx.TxOptions{
+				IsoLevel:       pgx.Serializable,
+				AccessMode:     pgx.ReadOnly,
+				DeferrableMode: "deferrable",
+				SnapshotID:     snapshotID,
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer workerTx.Rollback()
+
+			err = conn.QueryRow("select count(*) from snap_test").Scan(&counts[i])
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i, count := range counts {
+		if count != 2 {
+			t.Errorf("worker %d count => %v, want %v", i, count, 2)
+		}
+	}
+}
+
+func TestBatchSendWithSnapshotID(t *testing.T) {
+	t.Parallel()
+
+	exportConn := mustConnect(t, *defaultConnConfig)
+	defer closeConn(t, exportConn)
+
+	// A regular (not temporary) table, so the writer and worker connections
+	// can see it too.
+	mustExec(t, exportConn, "create table snap_batch_test(id serial primary key)")
+	defer mustExec(t, exportConn, "drop table snap_batch_test")
+	mustExec(t, exportConn, "insert into snap_batch_test default values")
+	mustExec(t, exportConn, "insert into snap_batch_test default values")
+
+	tx, err := exportConn.BeginEx(context.Background(), &pgx.TxOptions{
+		IsoLevel:       pgx.Serializable,
+		AccessMode:     pgx.ReadOnly,
+		DeferrableMode: "deferrable",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	snapshotID, err := tx.ExportSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writer := mustConnect(t, *defaultConnConfig)
+	defer closeConn(t, writer)
+	mustExec(t, writer, "insert into snap_batch_test default values")
+
+	const workerCount = 4
+	var wg sync.WaitGroup
+	counts := make([]int, workerCount)
+	errs := make([]error, workerCount)
+
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			conn := mustConnect(t, *defaultConnConfig)
+			defer closeConn(t, conn)
+
+			batch := conn.BeginBatch()
+			batch.Queue("select count(*) from snap_batch_test", nil, nil, []int16{pgx.BinaryFormatCode})
+			batch.Queue("select count(*) from snap_batch_test", nil, nil, []int16{pgx.BinaryFormatCode})
+
+			if err := batch.Send(context.Background(), &pgx.TxOptions{
+				IsoLevel:       pgx.Serializable,
+				AccessMode:     pgx.ReadOnly,
+				DeferrableMode: "deferrable",
+				SnapshotID:     snapshotID,
+			}); err != nil {
+				errs[i] = err
+				return
+			}
+			defer batch.Close()
+
+			var count1, count2 int
+			if err := batch.QueryRowResults().Scan(&count1); err != nil {
+				errs[i] = err
+				return
+			}
+			if err := batch.QueryRowResults().Scan(&count2); err != nil {
+				errs[i] = err
+				return
+			}
+
+			if count1 != count2 {
+				errs[i] = fmt.Errorf("count1 => %v, count2 => %v, want equal", count1, count2)
+				return
+			}
+
+			counts[i] = count1
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("worker %d: %v", i, err)
+		}
+	}
+	for i, count := range counts {
+		if count != 2 {
+			t.Errorf("worker %d count => %v, want %v (the writer's insert should not be visible)", i, count, 2)
+		}
+	}
+}
+
+func TestBatchSendWithTxOptionsClosesTransaction(t *testing.T) {
+	t.Parallel()
+
+	conn := mustConnect(t, *defaultConnConfig)
+	defer closeConn(t, conn)
+
+	mustExec(t, conn, "create table if not exists batch_tx_leak_test(id serial primary key)")
+	defer mustExec(t, conn, "drop table batch_tx_leak_test")
+
+	batch := conn.BeginBatch()
+	batch.Queue("select 1", nil, nil, []int16{pgx.BinaryFormatCode})
+
+	err := batch.Send(context.Background(), &pgx.TxOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var n int32
+	if err := batch.QueryRowResults().Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+
+	err = batch.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// If Close left the transaction Send opened still running, this insert
+	// would only become visible to other connections once that transaction
+	// is eventually committed or rolled back. Checking its visibility from a
+	// second connection right away proves Close actually ended it.
+	mustExec(t, conn, "insert into batch_tx_leak_test default values")
+
+	checker := mustConnect(t, *defaultConnConfig)
+	defer closeConn(t, checker)
+
+	var count int
+	err = checker.QueryRow("select count(*) from batch_tx_leak_test").Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("count => %v, want %v (Close should have ended the transaction Send opened)", count, 1)
+	}
+
+	ensureConnValid(t, conn)
+}
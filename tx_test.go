@@ -0,0 +1,19 @@
+package pgx
+
+import "testing"
+
+func TestQuoteSnapshotIDEscapesEmbeddedQuotes(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"10:20:10,14,15", "'10:20:10,14,15'"},
+		{"10:20:10,14,15'; drop table users; --", "'10:20:10,14,15''; drop table users; --'"},
+	}
+
+	for _, tt := range tests {
+		if got := quoteSnapshotID(tt.id); got != tt.want {
+			t.Errorf("quoteSnapshotID(%q) => %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}